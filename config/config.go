@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// SystemConfiguration groups the low-level, host-facing settings that
+// control how Wings talks to the kernel and to a server's console, as
+// opposed to the panel-facing settings that live alongside it in
+// Configuration.
+type SystemConfiguration struct {
+	// OpenatMode controls which syscall family UnixFS uses to resolve paths
+	// beneath a server's root.
+	OpenatMode OpenatMode `default:"auto" yaml:"openat_mode"`
+	// Console controls how daemon-originated console messages are rendered.
+	Console ConsoleConfiguration `yaml:"console"`
+	// Fuse controls whether a server's data directory is exposed through
+	// the ufs/fuse loopback filesystem.
+	Fuse FuseConfiguration `yaml:"fuse"`
+}
+
+// Configuration is the root of Wings' own configuration file.
+type Configuration struct {
+	System SystemConfiguration `yaml:"system"`
+	// Throttles is the default console output rate limit applied to every
+	// server that doesn't set its own override.
+	Throttles ConsoleThrottles `yaml:"throttles"`
+}
+
+var current atomic.Pointer[Configuration]
+
+// Get returns the active configuration. It is safe to call concurrently
+// with Set, and always returns a non-nil value, falling back to a
+// zero-value Configuration (with no defaults applied) if Set has never been
+// called.
+func Get() *Configuration {
+	cfg := current.Load()
+	if cfg == nil {
+		return &Configuration{}
+	}
+	return cfg
+}
+
+// Set installs cfg as the active configuration returned by Get, after
+// running it through Validate.
+func Set(cfg *Configuration) error {
+	if err := Validate(cfg.System); err != nil {
+		return err
+	}
+	current.Store(cfg)
+	return nil
+}
+
+var (
+	validatorsMu sync.Mutex
+	// validators are consulted by Validate in registration order. Packages
+	// that check SystemConfiguration fields but that config can't import
+	// without creating an import cycle (e.g. internal/ufs, which itself
+	// imports config) register a check here instead, mirroring
+	// server.RegisterConsoleFormatter's plugin pattern.
+	validators []func(SystemConfiguration) error
+)
+
+// RegisterSystemValidator adds fn to the checks Validate runs against a
+// SystemConfiguration before it's installed via Set. fn should return a
+// descriptive error identifying the offending field; Validate stops at the
+// first error.
+func RegisterSystemValidator(fn func(SystemConfiguration) error) {
+	validatorsMu.Lock()
+	defer validatorsMu.Unlock()
+	validators = append(validators, fn)
+}
+
+// Validate runs every validator registered via RegisterSystemValidator
+// against cfg, returning the first error encountered, so an invalid setting
+// (e.g. an OpenatMode the running kernel can't honor) fails loudly at
+// startup instead of silently misbehaving later.
+func Validate(cfg SystemConfiguration) error {
+	validatorsMu.Lock()
+	defer validatorsMu.Unlock()
+	for _, fn := range validators {
+		if err := fn(cfg); err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package config
+
+// ConsoleConfiguration controls how daemon-originated console messages (e.g.
+// throttle warnings, backup status) are rendered before being published to
+// a server's console.
+type ConsoleConfiguration struct {
+	// Formatter selects the registered ConsoleFormatter used to render
+	// daemon messages: "plain", "ansi", or "json", or the name of a
+	// formatter an integrator registered via
+	// server.RegisterConsoleFormatter.
+	Formatter string `default:"ansi" yaml:"formatter"`
+	// Prefix is the text shown before the message body, e.g. "Daemon".
+	Prefix string `default:"Daemon" yaml:"prefix"`
+	// EnableANSI, when false, makes the built-in "ansi" formatter render
+	// plain text with no ANSI escape sequences, for panels that render their
+	// own colors from the structured event instead. Formatters registered
+	// via server.RegisterConsoleFormatter are not affected by it.
+	EnableANSI bool `default:"true" yaml:"enable_ansi"`
+	// Palette maps semantic color names ("prefix", "body", "reset", ...) to
+	// ANSI escape sequences, used by ANSIFormatter.
+	Palette map[string]string `yaml:"palette"`
+	// Locale selects the message catalog used to translate built-in daemon
+	// messages (e.g. throttle warnings) before formatting. An empty value
+	// falls back to the compiled-in default strings.
+	Locale string `default:"" yaml:"locale"`
+	// Messages is a locale -> message-key -> translated string catalog,
+	// letting integrators override or add locales without forking Wings.
+	Messages map[string]map[string]string `yaml:"messages"`
+}
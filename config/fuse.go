@@ -0,0 +1,25 @@
+package config
+
+// FuseConfiguration controls whether a server's data directory is exposed
+// through the ufs/fuse loopback filesystem instead of the in-process UnixFS
+// sandbox. When enabled but unavailable on the host (e.g. no /dev/fuse),
+// Wings falls back to UnixFS automatically.
+type FuseConfiguration struct {
+	Enabled     bool   `default:"false" yaml:"enabled"`
+	AllowOther  bool   `default:"false" yaml:"allow_other"`
+	MaxWrite    int    `default:"1048576" yaml:"max_write"`
+	DirectIO    bool   `default:"false" yaml:"direct_io"`
+	ReaddirPlus bool   `default:"true" yaml:"readdirplus"`
+	MountRoot   string `default:"/var/lib/pterodactyl/fuse" yaml:"mount_root"`
+	// Uid and Gid are reported as the owner of every entry in the mount,
+	// regardless of the underlying file's real owner, so a container
+	// running as a fixed game-server user always sees a consistent,
+	// expected owner through the mount.
+	Uid uint32 `default:"0" yaml:"uid"`
+	Gid uint32 `default:"0" yaml:"gid"`
+	// DisallowedFiles lists paths, relative to the server's root, that may
+	// not be created, written to, or removed through the mount, letting
+	// Wings keep management files it relies on (e.g. its own metadata)
+	// safe from a process running inside the container.
+	DisallowedFiles []string `yaml:"disallowed_files"`
+}
@@ -0,0 +1,18 @@
+package config
+
+// OpenatMode controls which syscall family UnixFS uses to resolve paths
+// beneath a server's root.
+//
+//   - "auto" (the default) prefers openat2(2) when the running kernel
+//     supports it and falls back to openat(2) otherwise.
+//   - "openat" always uses the classic openat(2) path, even on kernels that
+//     support openat2(2).
+//   - "openat2" forces openat2(2) and causes startup to fail loudly if the
+//     kernel does not support it, rather than silently falling back.
+type OpenatMode string
+
+const (
+	OpenatModeAuto    OpenatMode = "auto"
+	OpenatModeOpenat  OpenatMode = "openat"
+	OpenatModeOpenat2 OpenatMode = "openat2"
+)
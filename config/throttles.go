@@ -0,0 +1,24 @@
+package config
+
+// ConsoleThrottles controls the token-bucket rate limiter applied to a
+// server's console output.
+type ConsoleThrottles struct {
+	Enabled bool `default:"true" yaml:"enabled"`
+	// Lines is the steady-state number of console lines allowed per Period.
+	Lines uint64 `default:"2000" yaml:"lines"`
+	// Period is the refill interval, in milliseconds, over which Lines
+	// tokens accumulate.
+	Period uint64 `default:"100" yaml:"period"`
+	// Burst caps how many lines may be emitted in a single burst (e.g.
+	// during server startup) before the bucket runs dry.
+	Burst uint64 `default:"6000" yaml:"burst"`
+	// WarnAfter is how long a sustained breach is tolerated, in
+	// milliseconds, before only warning the console.
+	WarnAfter uint64 `default:"0" yaml:"warn_after"`
+	// TerminateAfter is how long a sustained breach must persist, in
+	// milliseconds, before the server is terminated.
+	TerminateAfter uint64 `default:"10000" yaml:"terminate_after"`
+	// RecoverAfter is how long output must stay within limits, in
+	// milliseconds, before a strike is automatically cleared.
+	RecoverAfter uint64 `default:"10000" yaml:"recover_after"`
+}
@@ -0,0 +1,352 @@
+// Package fuse exposes a server's data directory as a loopback FUSE
+// filesystem, enforcing quota, disallowed-file, and ownership rules at the
+// VFS boundary so that any process inside the container is subject to them,
+// not just Wings itself.
+package fuse
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"syscall"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+
+	"github.com/pterodactyl/wings/internal/ufs"
+)
+
+// MountOptions controls how a server's root is exposed through FUSE.
+type MountOptions struct {
+	// AllowOther permits users other than the mount owner to access the
+	// filesystem; required when the game server process runs as a
+	// different uid/gid than Wings.
+	AllowOther bool
+	// MaxWrite caps the size of a single write request, mirroring the
+	// kernel's default but configurable for large modpack transfers.
+	MaxWrite int
+	// DirectIO disables the kernel page cache for file contents, trading
+	// throughput for a guarantee that quota checks see every write.
+	DirectIO bool
+	// ReaddirPlus lets the kernel request stat information alongside
+	// directory entries, avoiding a follow-up Lookup per entry.
+	ReaddirPlus bool
+	// Uid and Gid are reported as the owner of every entry in the mount,
+	// regardless of the underlying file's real owner, so a container
+	// running as a fixed game-server user always sees a consistent,
+	// expected owner through the mount.
+	Uid uint32
+	Gid uint32
+}
+
+// Root is the FUSE node handler for a server's mounted data directory. It
+// delegates ordinary file I/O to the wrapped UnixFS sandbox and additionally
+// enforces size/quota and disallowed-file rules on every write.
+type Root struct {
+	fs.Inode
+
+	root     *ufs.UnixFS
+	limit    QuotaChecker
+	policy   PathPolicy
+	owner    fuse.Owner
+	directIO bool
+}
+
+// QuotaChecker is consulted before every write so that limits are enforced
+// by the filesystem itself rather than by callers remembering to check.
+type QuotaChecker interface {
+	// Allow reports whether writing an additional n bytes keeps the
+	// server within its configured disk quota.
+	Allow(n int64) bool
+}
+
+// PathPolicy is consulted before a path is created, opened for writing, or
+// removed, letting callers reject disallowed paths (e.g. the management
+// files Wings itself relies on) at the VFS boundary instead of trusting
+// every process inside the container to leave them alone. A nil PathPolicy
+// allows everything.
+type PathPolicy interface {
+	// Allow reports whether relPath, a path relative to the mount root, may
+	// be created, opened for writing, or removed.
+	Allow(relPath string) bool
+}
+
+func (r *Root) allow(relPath string) bool {
+	return r.policy == nil || r.policy.Allow(relPath)
+}
+
+// VirtualFile is implemented by node handlers that back a well-known virtual
+// path (e.g. "/.wings/stats") instead of a file on disk.
+type VirtualFile interface {
+	ReadAll(ctx context.Context) ([]byte, error)
+}
+
+// Mount describes the lifecycle of a single server's FUSE mount. It is
+// created once per server and torn down when the server's container stops,
+// mirroring the power-state hooks used elsewhere for environment lifecycle.
+type Mount struct {
+	mu      sync.Mutex
+	path    string
+	opts    MountOptions
+	server  *fuse.Server
+	root    *Root
+	mounted bool
+}
+
+// NewMount prepares a FUSE mount rooted at path, backed by root, but does not
+// mount it yet. limit and policy may be nil, in which case writes and path
+// mutations are unrestricted.
+func NewMount(path string, root *ufs.UnixFS, limit QuotaChecker, policy PathPolicy, opts MountOptions) *Mount {
+	return &Mount{
+		path: path,
+		opts: opts,
+		root: &Root{
+			root:     root,
+			limit:    limit,
+			policy:   policy,
+			owner:    fuse.Owner{Uid: opts.Uid, Gid: opts.Gid},
+			directIO: opts.DirectIO,
+		},
+	}
+}
+
+// Root returns the node handler backing m, so callers can register virtual
+// files against it before (or after) mounting.
+func (m *Mount) Root() *Root {
+	return m.root
+}
+
+// Mount loopback-mounts the server root at m.path. It is safe to call
+// multiple times; subsequent calls are a no-op while already mounted.
+func (m *Mount) Mount() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.mounted {
+		return nil
+	}
+
+	srv, err := fs.Mount(m.path, m.root, &fs.Options{
+		MountOptions: fuse.MountOptions{
+			AllowOther:         m.opts.AllowOther,
+			MaxWrite:           m.opts.MaxWrite,
+			DisableReadDirPlus: !m.opts.ReaddirPlus,
+		},
+		UID: m.opts.Uid,
+		GID: m.opts.Gid,
+	})
+	if err != nil {
+		return fmt.Errorf("fuse: failed to mount %s: %w", m.path, err)
+	}
+
+	m.server = srv
+	m.mounted = true
+	return nil
+}
+
+// Unmount tears down the FUSE mount at m.path. It is safe to call on a mount
+// that was never mounted.
+func (m *Mount) Unmount() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.mounted {
+		return nil
+	}
+	if err := m.server.Unmount(); err != nil {
+		return fmt.Errorf("fuse: failed to unmount %s: %w", m.path, err)
+	}
+	m.mounted = false
+	m.server = nil
+	return nil
+}
+
+// Available reports whether FUSE mounting can be attempted on this host,
+// used by callers to fall back to the plain UnixFS sandbox when it cannot
+// (a missing /dev/fuse or no fusermount helper on PATH). Insufficient
+// privileges are not detected here; they surface as an error from Mount.
+func Available() bool {
+	return probeDevFuse()
+}
+
+var (
+	_ fs.NodeLookuper  = (*Root)(nil)
+	_ fs.NodeReaddirer = (*Root)(nil)
+	_ fs.NodeOpener    = (*Root)(nil)
+	_ fs.NodeCreater   = (*Root)(nil)
+	_ fs.NodeGetattrer = (*Root)(nil)
+	_ fs.NodeUnlinker  = (*Root)(nil)
+	_ fs.NodeMkdirer   = (*Root)(nil)
+	_ fs.NodeRmdirer   = (*Root)(nil)
+)
+
+// relPath returns r's path relative to the mount root, suitable for passing
+// straight to the wrapped UnixFS sandbox, which resolves every call relative
+// to its own root regardless of what the real underlying directory is named.
+func (r *Root) relPath() string {
+	return r.Path(nil)
+}
+
+// child returns a new, as-yet-unattached node sharing r's root, limit,
+// policy, owner, and directIO setting, for use as the target of NewInode
+// when a directory entry is looked up or created.
+func (r *Root) child() *Root {
+	return &Root{root: r.root, limit: r.limit, policy: r.policy, owner: r.owner, directIO: r.directIO}
+}
+
+// fillAttr populates out from info, always reporting r's configured owner
+// rather than the underlying file's real uid/gid, so every entry in the
+// mount shows a single, consistent owner regardless of what Wings itself
+// runs as.
+func (r *Root) fillAttr(out *fuse.Attr, info ufs.FileInfo) {
+	out.Mode = uint32(info.Mode())
+	out.Size = uint64(info.Size())
+	out.Mtime = uint64(info.ModTime().Unix())
+	out.Owner = r.owner
+}
+
+func stableAttrFor(info ufs.FileInfo) fs.StableAttr {
+	mode := uint32(syscall.S_IFREG)
+	if info.IsDir() {
+		mode = syscall.S_IFDIR
+	}
+	return fs.StableAttr{Mode: mode}
+}
+
+// Getattr reports the stat information for r itself, delegating to the
+// wrapped UnixFS sandbox rather than caching attributes on the node, so
+// changes made outside the mount (e.g. by Wings itself) are always visible.
+func (r *Root) Getattr(ctx context.Context, f fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
+	info, err := r.root.Stat(r.relPath())
+	if err != nil {
+		return fs.ToErrno(err)
+	}
+	r.fillAttr(&out.Attr, info)
+	return 0
+}
+
+// Lookup resolves name within r against the wrapped UnixFS sandbox, which
+// enforces the same path-confinement rules the in-process sandbox does.
+func (r *Root) Lookup(ctx context.Context, name string, out *fuse.EntryOut) (*fs.Inode, syscall.Errno) {
+	info, err := r.root.Stat(path.Join(r.relPath(), name))
+	if err != nil {
+		return nil, fs.ToErrno(err)
+	}
+	r.fillAttr(&out.Attr, info)
+	return r.NewInode(ctx, r.child(), stableAttrFor(info)), 0
+}
+
+// Readdir lists r's directory entries through the wrapped UnixFS sandbox.
+func (r *Root) Readdir(ctx context.Context) (fs.DirStream, syscall.Errno) {
+	entries, err := r.root.ReadDir(r.relPath())
+	if err != nil {
+		return nil, fs.ToErrno(err)
+	}
+	list := make([]fuse.DirEntry, 0, len(entries))
+	for _, e := range entries {
+		mode := uint32(syscall.S_IFREG)
+		if e.IsDir() {
+			mode = syscall.S_IFDIR
+		}
+		list = append(list, fuse.DirEntry{Name: e.Name(), Mode: mode})
+	}
+	return fs.NewListDirStream(list), 0
+}
+
+// wantsWrite reports whether flags, an open(2)-style flag set, requests
+// write access, which is what Open and Create gate against r.policy.
+func wantsWrite(flags int) bool {
+	return flags&(os.O_WRONLY|os.O_RDWR) != 0
+}
+
+// directIOFlags returns fuse.FOPEN_DIRECT_IO when r is configured for
+// direct I/O, disabling the kernel page cache for the handle so every quota
+// check sees every write rather than a coalesced one.
+func (r *Root) directIOFlags() uint32 {
+	if r.directIO {
+		return fuse.FOPEN_DIRECT_IO
+	}
+	return fuse.FOPEN_KEEP_CACHE
+}
+
+// Open opens r for reading or writing through the wrapped UnixFS sandbox.
+// Writes made through the returned handle are subject to r.limit, the same
+// as writes made through the in-process sandbox, and opening for writing is
+// itself subject to r.policy.
+func (r *Root) Open(ctx context.Context, flags uint32) (fs.FileHandle, uint32, syscall.Errno) {
+	if wantsWrite(int(flags)) && !r.allow(r.relPath()) {
+		return nil, 0, syscall.EPERM
+	}
+	f, err := r.root.OpenFile(r.relPath(), int(flags), 0o644)
+	if err != nil {
+		return nil, 0, fs.ToErrno(err)
+	}
+	return &fileHandle{file: f, limit: r.limit}, r.directIOFlags(), 0
+}
+
+// Create creates name within r and opens it for writing, enforcing r.limit
+// on the data written through the returned handle exactly as Open does for
+// an existing file, and rejecting disallowed paths via r.policy the same
+// way Open does for an existing file opened for writing.
+func (r *Root) Create(ctx context.Context, name string, flags uint32, mode uint32, out *fuse.EntryOut) (*fs.Inode, fs.FileHandle, uint32, syscall.Errno) {
+	rel := path.Join(r.relPath(), name)
+	if !r.allow(rel) {
+		return nil, nil, 0, syscall.EPERM
+	}
+	f, err := r.root.OpenFile(rel, int(flags)|os.O_CREATE, ufs.FileMode(mode))
+	if err != nil {
+		return nil, nil, 0, fs.ToErrno(err)
+	}
+	info, err := r.root.Stat(rel)
+	if err != nil {
+		f.Close()
+		return nil, nil, 0, fs.ToErrno(err)
+	}
+	r.fillAttr(&out.Attr, info)
+	inode := r.NewInode(ctx, r.child(), stableAttrFor(info))
+	return inode, &fileHandle{file: f, limit: r.limit}, r.directIOFlags(), 0
+}
+
+// Mkdir creates a subdirectory of r through the wrapped UnixFS sandbox,
+// subject to r.policy the same way Create is.
+func (r *Root) Mkdir(ctx context.Context, name string, mode uint32, out *fuse.EntryOut) (*fs.Inode, syscall.Errno) {
+	rel := path.Join(r.relPath(), name)
+	if !r.allow(rel) {
+		return nil, syscall.EPERM
+	}
+	if err := r.root.Mkdir(rel, ufs.FileMode(mode)); err != nil {
+		return nil, fs.ToErrno(err)
+	}
+	info, err := r.root.Stat(rel)
+	if err != nil {
+		return nil, fs.ToErrno(err)
+	}
+	r.fillAttr(&out.Attr, info)
+	return r.NewInode(ctx, r.child(), stableAttrFor(info)), 0
+}
+
+// Unlink removes a file from r through the wrapped UnixFS sandbox, subject
+// to r.policy the same way Create is.
+func (r *Root) Unlink(ctx context.Context, name string) syscall.Errno {
+	rel := path.Join(r.relPath(), name)
+	if !r.allow(rel) {
+		return syscall.EPERM
+	}
+	if err := r.root.Remove(rel); err != nil {
+		return fs.ToErrno(err)
+	}
+	return 0
+}
+
+// Rmdir removes an empty subdirectory of r through the wrapped UnixFS
+// sandbox, subject to r.policy the same way Unlink is.
+func (r *Root) Rmdir(ctx context.Context, name string) syscall.Errno {
+	rel := path.Join(r.relPath(), name)
+	if !r.allow(rel) {
+		return syscall.EPERM
+	}
+	if err := r.root.Remove(rel); err != nil {
+		return fs.ToErrno(err)
+	}
+	return 0
+}
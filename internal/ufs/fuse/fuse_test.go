@@ -0,0 +1,77 @@
+package fuse
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pterodactyl/wings/internal/ufs"
+)
+
+// requireFuse skips the test on hosts where /dev/fuse isn't available (e.g.
+// most CI containers), since mounting is the only way to exercise Root
+// end-to-end through the kernel rather than by calling its methods directly.
+func requireFuse(t *testing.T) {
+	t.Helper()
+	if !Available() {
+		t.Skip("fuse: /dev/fuse not available, skipping mount test")
+	}
+}
+
+// TestMountReadWrite mounts a server root backed by a temp directory and
+// confirms a file written through the mount is visible both through the
+// mount itself and on the underlying directory, and that a write exceeding
+// the QuotaChecker is rejected.
+func TestMountReadWrite(t *testing.T) {
+	requireFuse(t)
+
+	src := t.TempDir()
+	mountPoint := t.TempDir()
+
+	root, err := ufs.New(src)
+	if err != nil {
+		t.Fatalf("ufs.New: %v", err)
+	}
+
+	quota := &fakeQuota{limit: 1024}
+	m := NewMount(mountPoint, root, quota, nil, MountOptions{})
+
+	if err := m.Mount(); err != nil {
+		t.Fatalf("Mount: %v", err)
+	}
+	defer m.Unmount()
+
+	content := []byte("hello from fuse")
+	if err := os.WriteFile(filepath.Join(mountPoint, "greeting.txt"), content, 0o644); err != nil {
+		t.Fatalf("write through mount: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(src, "greeting.txt"))
+	if err != nil {
+		t.Fatalf("read from underlying directory: %v", err)
+	}
+	if string(got) != string(content) {
+		t.Fatalf("underlying file = %q, want %q", got, content)
+	}
+
+	got, err = os.ReadFile(filepath.Join(mountPoint, "greeting.txt"))
+	if err != nil {
+		t.Fatalf("read through mount: %v", err)
+	}
+	if string(got) != string(content) {
+		t.Fatalf("mounted file = %q, want %q", got, content)
+	}
+
+	quota.limit = 0
+	if err := os.WriteFile(filepath.Join(mountPoint, "toobig.txt"), []byte("x"), 0o644); err == nil {
+		t.Fatal("expected write exceeding quota to fail, got nil error")
+	}
+}
+
+type fakeQuota struct {
+	limit int64
+}
+
+func (q *fakeQuota) Allow(n int64) bool {
+	return n <= q.limit
+}
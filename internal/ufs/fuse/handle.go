@@ -0,0 +1,70 @@
+package fuse
+
+import (
+	"context"
+	"io"
+	"sync"
+	"syscall"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+
+	"github.com/pterodactyl/wings/internal/ufs"
+)
+
+var (
+	_ fs.FileReader   = (*fileHandle)(nil)
+	_ fs.FileWriter   = (*fileHandle)(nil)
+	_ fs.FileFlusher  = (*fileHandle)(nil)
+	_ fs.FileReleaser = (*fileHandle)(nil)
+)
+
+// fileHandle backs an open file through the wrapped UnixFS sandbox, applying
+// limit to every write so quota is enforced at the VFS boundary and not just
+// by callers that remember to check it themselves.
+type fileHandle struct {
+	mu    sync.Mutex
+	file  ufs.File
+	limit QuotaChecker
+}
+
+func (h *fileHandle) Read(ctx context.Context, dest []byte, off int64) (fuse.ReadResult, syscall.Errno) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	n, err := h.file.ReadAt(dest, off)
+	if err != nil && err != io.EOF {
+		return nil, syscall.EIO
+	}
+	return fuse.ReadResultData(dest[:n]), 0
+}
+
+func (h *fileHandle) Write(ctx context.Context, data []byte, off int64) (uint32, syscall.Errno) {
+	if h.limit != nil && !h.limit.Allow(int64(len(data))) {
+		return 0, syscall.EDQUOT
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	n, err := h.file.WriteAt(data, off)
+	if err != nil {
+		return 0, syscall.EIO
+	}
+	return uint32(n), 0
+}
+
+func (h *fileHandle) Flush(ctx context.Context) syscall.Errno {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := h.file.Sync(); err != nil {
+		return syscall.EIO
+	}
+	return 0
+}
+
+func (h *fileHandle) Release(ctx context.Context) syscall.Errno {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := h.file.Close(); err != nil {
+		return syscall.EIO
+	}
+	return 0
+}
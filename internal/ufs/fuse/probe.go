@@ -0,0 +1,25 @@
+package fuse
+
+import (
+	"os"
+	"os/exec"
+)
+
+// probeDevFuse reports whether the /dev/fuse device node exists and the
+// fusermount helper go-fuse shells out to for mounting is on PATH, both of
+// which are required before attempting Mount. It does not check that the
+// device can actually be opened by the current process. Containers
+// commonly expose the /dev/fuse device node without installing the
+// userspace fusermount binary, so checking the device alone isn't enough to
+// predict whether Mount will actually succeed.
+func probeDevFuse() bool {
+	if _, err := os.Stat("/dev/fuse"); err != nil {
+		return false
+	}
+	for _, name := range []string{"fusermount3", "fusermount"} {
+		if _, err := exec.LookPath(name); err == nil {
+			return true
+		}
+	}
+	return false
+}
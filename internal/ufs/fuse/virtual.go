@@ -0,0 +1,121 @@
+package fuse
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+)
+
+// virtualNode exposes a VirtualFile under a well-known path such as
+// "/.wings/stats" or "/.wings/console" without touching the underlying
+// server directory.
+type virtualNode struct {
+	fs.Inode
+
+	file VirtualFile
+
+	mu       sync.Mutex
+	cached   []byte
+	cachedOK bool
+}
+
+var _ fs.NodeOpener = (*virtualNode)(nil)
+var _ fs.NodeReader = (*virtualNode)(nil)
+var _ fs.NodeGetattrer = (*virtualNode)(nil)
+
+// virtualDir is a plain in-memory directory inode with no backing on the
+// underlying server directory, used to group virtual files (e.g. under
+// "/.wings") without exposing them as top-level mount entries. go-fuse
+// serves Lookup/Readdir for it out of its statically added children with no
+// method overrides required.
+type virtualDir struct {
+	fs.Inode
+}
+
+// RegisterVirtualFile attaches a VirtualFile at relPath (a "/"-separated
+// path relative to root, e.g. ".wings/stats"), creating any missing parent
+// directories as plain virtualDir nodes, so it appears as a regular
+// (read-only) file to anything reading the mount.
+func (r *Root) RegisterVirtualFile(ctx context.Context, relPath string, file VirtualFile) {
+	parts := strings.Split(strings.Trim(relPath, "/"), "/")
+
+	dir := &r.Inode
+	for _, part := range parts[:len(parts)-1] {
+		if child := dir.GetChild(part); child != nil {
+			dir = child
+			continue
+		}
+		child := dir.NewPersistentInode(ctx, &virtualDir{}, fs.StableAttr{Mode: syscall.S_IFDIR})
+		dir.AddChild(part, child, true)
+		dir = child
+	}
+
+	name := parts[len(parts)-1]
+	node := &virtualNode{file: file}
+	dir.AddChild(name, dir.NewPersistentInode(ctx, node, fs.StableAttr{Mode: syscall.S_IFREG}), true)
+}
+
+// fill populates n.cached from n.file.ReadAll if it isn't populated yet.
+// Open clears the cache before calling it, so every open sees fresh
+// content, while Read and Getattr reuse what the last open read. Callers
+// must hold n.mu.
+func (n *virtualNode) fill(ctx context.Context) syscall.Errno {
+	if n.cachedOK {
+		return 0
+	}
+	data, err := n.file.ReadAll(ctx)
+	if err != nil {
+		return syscall.EIO
+	}
+	n.cached = data
+	n.cachedOK = true
+	return 0
+}
+
+func (n *virtualNode) Open(ctx context.Context, flags uint32) (fs.FileHandle, uint32, syscall.Errno) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.cachedOK = false
+	if errno := n.fill(ctx); errno != 0 {
+		return nil, 0, errno
+	}
+	// Direct I/O keeps the kernel from serving stale page-cache contents
+	// or truncating reads to a size reported before the content changed.
+	return nil, fuse.FOPEN_DIRECT_IO, 0
+}
+
+// Read serves dest out of the cached ReadAll result populated by Open, since
+// Open returns no FileHandle for virtual files to back reads with.
+func (n *virtualNode) Read(ctx context.Context, f fs.FileHandle, dest []byte, off int64) (fuse.ReadResult, syscall.Errno) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if errno := n.fill(ctx); errno != 0 {
+		return nil, errno
+	}
+	end := off + int64(len(dest))
+	if end > int64(len(n.cached)) {
+		end = int64(len(n.cached))
+	}
+	if off > end {
+		off = end
+	}
+	return fuse.ReadResultData(n.cached[off:end]), 0
+}
+
+// Getattr reports the virtual file's size, triggering the same lazy
+// ReadAll fill as Open/Read so stat() reflects the real size even before
+// anything has opened the file.
+func (n *virtualNode) Getattr(ctx context.Context, f fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	out.Mode = syscall.S_IFREG | 0444
+	if errno := n.fill(ctx); errno != 0 {
+		return errno
+	}
+	out.Size = uint64(len(n.cached))
+	return 0
+}
@@ -0,0 +1,206 @@
+//go:build linux
+
+package ufs
+
+import (
+	"fmt"
+	iofs "io/fs"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/pterodactyl/wings/config"
+)
+
+func init() {
+	config.RegisterSystemValidator(func(cfg config.SystemConfiguration) error {
+		return ValidateOpenatMode(cfg.OpenatMode)
+	})
+}
+
+// resolveBeneath is the combination of openat2(2) resolve flags that keeps
+// path resolution confined to the directory tree rooted at the starting
+// dirfd: it rejects absolute symlinks and ".." components that would escape
+// the root, refuses to follow "magic links" (e.g. /proc/*/fd entries), and
+// refuses to cross into a different mount.
+const resolveBeneath = unix.RESOLVE_BENEATH | unix.RESOLVE_NO_MAGICLINKS | unix.RESOLVE_NO_XDEV
+
+var (
+	openat2Once      sync.Once
+	openat2Supported bool
+)
+
+// openat2Available probes the running kernel for openat2(2) support with
+// RESOLVE_BENEATH once per process and caches the result. The probe opens
+// "/" against itself, which is cheap and side-effect free.
+func openat2Available() bool {
+	openat2Once.Do(func() {
+		rootfd, err := unix.Open("/", unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+		if err != nil {
+			return
+		}
+		defer unix.Close(rootfd)
+		fd, err := unix.Openat2(rootfd, ".", &unix.OpenHow{
+			Flags:   unix.O_RDONLY | unix.O_DIRECTORY,
+			Resolve: resolveBeneath,
+		})
+		if err == nil {
+			_ = unix.Close(fd)
+			openat2Supported = true
+		}
+	})
+	return openat2Supported
+}
+
+// useOpenat2 reports whether openat2(2) should be attempted for the given
+// configuration mode, taking the cached kernel capability probe into
+// account.
+func useOpenat2(mode config.OpenatMode) bool {
+	switch mode {
+	case config.OpenatModeOpenat:
+		return false
+	case config.OpenatModeOpenat2:
+		return true
+	default:
+		return openat2Available()
+	}
+}
+
+// ValidateOpenatMode checks that the configured OpenatMode can actually be
+// honored by the running kernel. "auto" and "openat" always succeed, since
+// both have a usable fallback; "openat2" fails loudly here, at startup,
+// rather than silently falling back to openat(2) later at the first ENOSYS.
+func ValidateOpenatMode(mode config.OpenatMode) error {
+	if mode == config.OpenatModeOpenat2 && !openat2Available() {
+		return fmt.Errorf("ufs: openat_mode %q requires kernel support for openat2(2), which was not detected", mode)
+	}
+	return nil
+}
+
+// openatAny opens name beneath dirfd, preferring the openat2(2) fast path
+// when the configured OpenatMode and kernel support allow it, and falling
+// back to the classic openat(2) implementation otherwise. When OpenatMode is
+// forced to "openat2" an ENOSYS is returned to the caller instead of falling
+// back, matching ValidateOpenatMode's promise that this mode never silently
+// degrades; "auto" still falls back, since ValidateOpenatMode was never
+// asked to guarantee kernel support for it.
+func (fs *UnixFS) openatAny(dirfd int, name string, flags int, mode uint32) (int, error) {
+	m := config.Get().System.OpenatMode
+	if useOpenat2(m) {
+		fd, err := openat2(dirfd, name, flags, mode)
+		if err == nil || err != unix.ENOSYS || m == config.OpenatModeOpenat2 {
+			return fd, err
+		}
+	}
+	return fs.openat(dirfd, name, flags, mode)
+}
+
+// lstatatAny resolves name beneath dirfd via the openat2(2) fast path (when
+// enabled), then stats the fd it just opened directly with Fstatat rather
+// than discarding it and re-resolving name by its classic Lstatat, which
+// would reintroduce the TOCTOU window openat2(2)'s RESOLVE_BENEATH exists
+// to close: name could be swapped for something else between the two
+// lookups.
+func (fs *UnixFS) lstatatAny(dirfd int, name string) (FileInfo, error) {
+	m := config.Get().System.OpenatMode
+	if useOpenat2(m) {
+		fd, err := openat2(dirfd, name, unix.O_PATH|unix.O_NOFOLLOW, 0)
+		if err == nil {
+			defer unix.Close(fd)
+			return fstatFd(fd, name)
+		}
+		if err != unix.ENOSYS || m == config.OpenatModeOpenat2 {
+			return nil, err
+		}
+	}
+	return fs.Lstatat(dirfd, name)
+}
+
+// openFileatAny opens name beneath dirfd via the openat2(2) fast path (when
+// enabled) with the real flags and mode the caller asked for, rather than
+// probing with a throwaway O_PATH open and then reopening name by its
+// classic OpenFileat, which would reintroduce the same TOCTOU window
+// lstatatAny avoids and cost a second syscall on every open.
+func (fs *UnixFS) openFileatAny(dirfd int, name string, flags int, mode uint32) (File, error) {
+	m := config.Get().System.OpenatMode
+	if useOpenat2(m) {
+		fd, err := openat2(dirfd, name, flags, mode)
+		if err == nil {
+			return os.NewFile(uintptr(fd), name), nil
+		}
+		if err != unix.ENOSYS || m == config.OpenatModeOpenat2 {
+			return nil, err
+		}
+	}
+	return fs.OpenFileat(dirfd, name, flags, mode)
+}
+
+// fstatFd stats fd directly via Fstatat with AT_EMPTY_PATH, reporting name
+// as the entry's name, instead of resolving a path by name a second time.
+func fstatFd(fd int, name string) (FileInfo, error) {
+	var st unix.Stat_t
+	if err := unix.Fstatat(fd, "", &st, unix.AT_EMPTY_PATH); err != nil {
+		return nil, err
+	}
+	return fstatFileInfo{name: name, st: st}, nil
+}
+
+// fstatFileInfo is a minimal FileInfo backed directly by a raw unix.Stat_t,
+// letting fstatFd report an Fstatat result without needing any of the
+// concrete types the classic by-name stat implementations use internally.
+type fstatFileInfo struct {
+	name string
+	st   unix.Stat_t
+}
+
+func (fi fstatFileInfo) Name() string       { return fi.name }
+func (fi fstatFileInfo) Size() int64        { return fi.st.Size }
+func (fi fstatFileInfo) Mode() FileMode     { return unixFileModeFromStat(fi.st.Mode) }
+func (fi fstatFileInfo) ModTime() time.Time { return time.Unix(fi.st.Mtim.Sec, fi.st.Mtim.Nsec) }
+func (fi fstatFileInfo) IsDir() bool        { return fi.Mode().IsDir() }
+func (fi fstatFileInfo) Sys() any           { return &fi.st }
+
+// unixFileModeFromStat converts a raw st_mode into an io/fs.FileMode, the
+// same bit mapping the standard library uses internally to build os.FileInfo
+// from a syscall stat result.
+func unixFileModeFromStat(mode uint32) FileMode {
+	fm := FileMode(mode & 0777)
+	switch mode & unix.S_IFMT {
+	case unix.S_IFBLK:
+		fm |= iofs.ModeDevice
+	case unix.S_IFCHR:
+		fm |= iofs.ModeDevice | iofs.ModeCharDevice
+	case unix.S_IFDIR:
+		fm |= iofs.ModeDir
+	case unix.S_IFIFO:
+		fm |= iofs.ModeNamedPipe
+	case unix.S_IFLNK:
+		fm |= iofs.ModeSymlink
+	case unix.S_IFSOCK:
+		fm |= iofs.ModeSocket
+	}
+	if mode&unix.S_ISGID != 0 {
+		fm |= iofs.ModeSetgid
+	}
+	if mode&unix.S_ISUID != 0 {
+		fm |= iofs.ModeSetuid
+	}
+	if mode&unix.S_ISVTX != 0 {
+		fm |= iofs.ModeSticky
+	}
+	return fm
+}
+
+// openat2 resolves name beneath dirfd using openat2(2) with RESOLVE_BENEATH,
+// letting the kernel atomically reject symlink escapes, absolute components,
+// ".." climbs past the root, and cross-mount traversal that the classic
+// openat(2) + manual scrubbing approach can only approximate.
+func openat2(dirfd int, name string, flags int, mode uint32) (int, error) {
+	return unix.Openat2(dirfd, name, &unix.OpenHow{
+		Flags:   uint64(flags),
+		Mode:    uint64(mode),
+		Resolve: resolveBeneath,
+	})
+}
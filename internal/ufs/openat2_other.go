@@ -0,0 +1,45 @@
+//go:build unix && !linux
+
+package ufs
+
+import (
+	"fmt"
+
+	"github.com/pterodactyl/wings/config"
+)
+
+func init() {
+	config.RegisterSystemValidator(func(cfg config.SystemConfiguration) error {
+		return ValidateOpenatMode(cfg.OpenatMode)
+	})
+}
+
+// openat2(2) is a Linux-only syscall; on other unix platforms we always fall
+// back to the classic openat(2) path regardless of the configured mode.
+func useOpenat2(mode config.OpenatMode) bool {
+	return false
+}
+
+// ValidateOpenatMode rejects "openat2" mode outright on platforms that can
+// never support the syscall, rather than silently falling back at runtime.
+func ValidateOpenatMode(mode config.OpenatMode) error {
+	if mode == config.OpenatModeOpenat2 {
+		return fmt.Errorf("ufs: openat_mode %q is not supported on this platform", mode)
+	}
+	return nil
+}
+
+// openatAny, lstatatAny, and openFileatAny always fall back to the classic
+// implementations on platforms without openat2(2).
+
+func (fs *UnixFS) openatAny(dirfd int, name string, flags int, mode uint32) (int, error) {
+	return fs.openat(dirfd, name, flags, mode)
+}
+
+func (fs *UnixFS) lstatatAny(dirfd int, name string) (FileInfo, error) {
+	return fs.Lstatat(dirfd, name)
+}
+
+func (fs *UnixFS) openFileatAny(dirfd int, name string, flags int, mode uint32) (File, error) {
+	return fs.OpenFileat(dirfd, name, flags, mode)
+}
@@ -0,0 +1,111 @@
+//go:build unix
+
+package ufs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fixtureHelper is satisfied by both *testing.T and *testing.B, letting
+// benchTree back both benchmarks and the deadlock/correctness regression
+// tests in walk_parallel_test.go with the same fixture builder.
+type fixtureHelper interface {
+	Helper()
+	TempDir() string
+	Fatalf(format string, args ...any)
+}
+
+// benchTree creates a directory tree with width entries at each of depth
+// levels, returning its root path for BenchmarkReadDir/BenchmarkWalkDir to
+// exercise the getdents hot loop.
+func benchTree(b fixtureHelper, width, depth int) string {
+	b.Helper()
+	root := b.TempDir()
+
+	var populate func(dir string, depth int)
+	populate = func(dir string, depth int) {
+		for i := 0; i < width; i++ {
+			if err := os.WriteFile(filepath.Join(dir, "file"+itoa(i)), nil, 0o644); err != nil {
+				b.Fatalf("write fixture file: %v", err)
+			}
+		}
+		if depth == 0 {
+			return
+		}
+		for i := 0; i < width; i++ {
+			sub := filepath.Join(dir, "dir"+itoa(i))
+			if err := os.Mkdir(sub, 0o755); err != nil {
+				b.Fatalf("mkdir fixture dir: %v", err)
+			}
+			populate(sub, depth-1)
+		}
+	}
+	populate(root, depth)
+	return root
+}
+
+func itoa(i int) string {
+	if i == 0 {
+		return "0"
+	}
+	var buf [20]byte
+	pos := len(buf)
+	for i > 0 {
+		pos--
+		buf[pos] = byte('0' + i%10)
+		i /= 10
+	}
+	return string(buf[pos:])
+}
+
+func BenchmarkReadDir(b *testing.B) {
+	fs, err := New(benchTree(b, 64, 0))
+	if err != nil {
+		b.Fatalf("open fixture root: %v", err)
+	}
+
+	dirfd, name, closeFd, err := fs.safePath(".")
+	defer closeFd()
+	if err != nil {
+		b.Fatalf("safePath: %v", err)
+	}
+	fd, err := fs.openat(dirfd, name, O_DIRECTORY|O_RDONLY, 0)
+	if err != nil {
+		b.Fatalf("openat: %v", err)
+	}
+	defer os.NewFile(uintptr(fd), ".").Close()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := fs.readDir(fd, ".", ".", nil); err != nil {
+			b.Fatalf("readDir: %v", err)
+		}
+	}
+}
+
+func BenchmarkWalkDir(b *testing.B) {
+	root := benchTree(b, 8, 3)
+	fs, err := New(root)
+	if err != nil {
+		b.Fatalf("open fixture root: %v", err)
+	}
+
+	dirfd, name, closeFd, err := fs.safePath(".")
+	defer closeFd()
+	if err != nil {
+		b.Fatalf("safePath: %v", err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := fs.WalkDirat(dirfd, name, func(dirfd int, name, relative string, d DirEntry, err error) error {
+			return err
+		}); err != nil {
+			b.Fatalf("WalkDirat: %v", err)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+//go:build unix
+
+package ufs
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// runWalk runs fn and fails the test if it doesn't return within d, guarding
+// against regressions like the depth-vs-concurrency deadlock this walker
+// used to hit.
+func runWalk(t *testing.T, d time.Duration, fn func() error) error {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(d):
+		t.Fatalf("WalkDiratParallel did not return within %s, likely deadlocked", d)
+		return nil
+	}
+}
+
+func TestWalkDiratParallelVisitsEverything(t *testing.T) {
+	root := benchTree(t, 4, 3)
+	fs, err := New(root)
+	if err != nil {
+		t.Fatalf("open fixture root: %v", err)
+	}
+	dirfd, name, closeFd, err := fs.safePath(".")
+	defer closeFd()
+	if err != nil {
+		t.Fatalf("safePath: %v", err)
+	}
+
+	var sequential, parallel int64
+	if err := fs.WalkDirat(dirfd, name, func(dirfd int, name, relative string, d DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		atomic.AddInt64(&sequential, 1)
+		return nil
+	}); err != nil {
+		t.Fatalf("WalkDirat: %v", err)
+	}
+
+	err = runWalk(t, 10*time.Second, func() error {
+		return fs.WalkDiratParallel(dirfd, name, WalkOptions{Concurrency: 4}, func(dirfd int, name, relative string, d DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			atomic.AddInt64(&parallel, 1)
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("WalkDiratParallel: %v", err)
+	}
+
+	if sequential != parallel {
+		t.Fatalf("WalkDiratParallel visited %d entries, sequential WalkDirat visited %d", parallel, sequential)
+	}
+}
+
+// TestWalkDiratParallelDeepTreeSingleWorker guards against the deadlock
+// where a worker held its semaphore slot while waiting on its own
+// descendants, starving the very children it was waiting for whenever tree
+// depth exceeded the configured concurrency.
+func TestWalkDiratParallelDeepTreeSingleWorker(t *testing.T) {
+	root := benchTree(t, 1, 12)
+	fs, err := New(root)
+	if err != nil {
+		t.Fatalf("open fixture root: %v", err)
+	}
+	dirfd, name, closeFd, err := fs.safePath(".")
+	defer closeFd()
+	if err != nil {
+		t.Fatalf("safePath: %v", err)
+	}
+
+	err = runWalk(t, 10*time.Second, func() error {
+		return fs.WalkDiratParallel(dirfd, name, WalkOptions{Concurrency: 1}, func(dirfd int, name, relative string, d DirEntry, err error) error {
+			return err
+		})
+	})
+	if err != nil {
+		t.Fatalf("WalkDiratParallel: %v", err)
+	}
+}
+
+func TestWalkDiratParallelSkipDirSkipsSiblingFiles(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"a", "b", "c"} {
+		if err := os.WriteFile(filepath.Join(root, name), nil, 0o644); err != nil {
+			t.Fatalf("write fixture file: %v", err)
+		}
+	}
+
+	fs, err := New(root)
+	if err != nil {
+		t.Fatalf("open fixture root: %v", err)
+	}
+	dirfd, name, closeFd, err := fs.safePath(".")
+	defer closeFd()
+	if err != nil {
+		t.Fatalf("safePath: %v", err)
+	}
+
+	var mu sync.Mutex
+	var visited []string
+	err = runWalk(t, 10*time.Second, func() error {
+		return fs.WalkDiratParallel(dirfd, name, WalkOptions{Concurrency: 2, SortEntries: true}, func(dirfd int, n, relative string, d DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d != nil && !d.IsDir() {
+				mu.Lock()
+				visited = append(visited, d.Name())
+				mu.Unlock()
+				if d.Name() == "a" {
+					return SkipDir
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("WalkDiratParallel: %v", err)
+	}
+	if len(visited) != 1 {
+		t.Fatalf("expected SkipDir to stop remaining siblings from being visited, got %v", visited)
+	}
+}
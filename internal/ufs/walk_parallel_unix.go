@@ -0,0 +1,270 @@
+//go:build unix
+
+package ufs
+
+import (
+	"context"
+	"errors"
+	iofs "io/fs"
+	"runtime"
+	"sort"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+// WalkOptions configures WalkDiratParallel.
+type WalkOptions struct {
+	// Concurrency bounds how many directories are opened and read at once.
+	// A value <= 0 defaults to runtime.GOMAXPROCS(0). Each subdirectory is
+	// walked on its own goroutine, so neither the number of goroutines nor
+	// the number of concurrent fn calls is bounded by it.
+	Concurrency int
+	// SortEntries walks each directory's entries in lexical order. This
+	// costs a sort per directory but makes output (and test fixtures)
+	// deterministic.
+	SortEntries bool
+	// FollowSymlinks causes symlinked directories to be resolved and walked
+	// as if they were ordinary directories, rather than reported as a
+	// symlink leaf. Symlinks to non-directories are still reported as a
+	// leaf even with this set.
+	FollowSymlinks bool
+}
+
+// scratchPool hands out page-sized getdents buffers to parallel walk workers
+// so concurrent directories don't each allocate their own.
+var scratchPool = sync.Pool{
+	New: func() any {
+		return newScratchBuffer()
+	},
+}
+
+// walkParallelTask is a single directory dispatched to a worker goroutine.
+type walkParallelTask struct {
+	dirfd    int
+	name     string
+	relative string
+	entry    DirEntry
+}
+
+// followedSymlinkEntry reports IsDir() as true for a DirEntry that is
+// actually a symlink resolving to a directory, so walkDirParallel's leaf
+// check treats it as a directory to recurse into rather than a leaf.
+type followedSymlinkEntry struct {
+	DirEntry
+}
+
+func (followedSymlinkEntry) IsDir() bool { return true }
+
+// symlinkGuard tracks the device/inode pairs FollowSymlinks has already
+// resolved and recursed into during a single WalkDiratParallel call, so a
+// symlink cycle (e.g. a directory containing a symlink to an ancestor)
+// terminates instead of recursing without bound.
+type symlinkGuard struct {
+	mu      sync.Mutex
+	visited map[[2]uint64]struct{}
+}
+
+func newSymlinkGuard() *symlinkGuard {
+	return &symlinkGuard{visited: make(map[[2]uint64]struct{})}
+}
+
+// visit records target as seen, returning false if it was already seen and
+// so must not be recursed into again. A target whose Sys() isn't a
+// *unix.Stat_t is never deduplicated (always returns true), since a
+// filesystem that can't report inode numbers can't form the dev/ino cycles
+// this guard exists to catch.
+func (g *symlinkGuard) visit(target FileInfo) bool {
+	st, ok := target.Sys().(*unix.Stat_t)
+	if !ok {
+		return true
+	}
+	key := [2]uint64{uint64(st.Dev), uint64(st.Ino)}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, seen := g.visited[key]; seen {
+		return false
+	}
+	g.visited[key] = struct{}{}
+	return true
+}
+
+// WalkDiratParallel walks the tree rooted at name (relative to dirfd) the
+// same way WalkDirat does, but walks each subdirectory on its own goroutine
+// instead of recursing sequentially, with at most opts.Concurrency
+// directories being opened and read at once. fn may be called from any
+// goroutine, including concurrently. The non-directory entries of a single
+// directory are all delivered, in order, from the goroutine that read that
+// directory; each subdirectory entry is delivered from the goroutine that
+// then walks it, not together with its siblings.
+//
+// A SkipDir returned from fn for a directory prunes only the subtree rooted
+// at that directory. A SkipDir returned for a non-directory entry skips the
+// entries that follow it in its directory, as WalkDirat does; subdirectories
+// already handed to their own goroutines are still walked. A SkipAll
+// cancels the shared walk context, allowing
+// in-flight workers to wind down without visiting further directories.
+// Errors from concurrent workers are combined with errors.Join.
+func (fs *UnixFS) WalkDiratParallel(dirfd int, name string, opts WalkOptions, fn WalkDiratFunc) error {
+	info, err := fs.lstatatAny(dirfd, name)
+	if err != nil {
+		return fn(dirfd, name, ".", nil, err)
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// sem bounds the number of directories doing readdir work at once. A
+	// walkDirParallel call only holds a slot while actually opening and
+	// reading its own directory; it releases the slot before blocking on its
+	// children, so a tree deeper than concurrency never deadlocks waiting
+	// for a slot an ancestor is sitting on.
+	sem := make(chan struct{}, concurrency)
+
+	// visited guards opts.FollowSymlinks against symlink cycles; it's
+	// shared across every worker for the lifetime of this call.
+	visited := newSymlinkGuard()
+
+	var mu sync.Mutex
+	var errs []error
+	var skipAll bool
+
+	// dispatch spawns a worker goroutine for t and registers it against
+	// childDone, the WaitGroup of whichever directory owns t.dirfd. Because
+	// each walkDirParallel call waits on its own children before returning,
+	// waiting on the root task's childDone transitively waits for the whole
+	// tree.
+	var dispatch func(t walkParallelTask, childDone *sync.WaitGroup)
+	dispatch = func(t walkParallelTask, childDone *sync.WaitGroup) {
+		childDone.Add(1)
+		go func() {
+			defer childDone.Done()
+
+			if ctx.Err() != nil {
+				return
+			}
+			err := fs.walkDirParallel(ctx, t.dirfd, t.name, t.relative, t.entry, opts, fn, dispatch, sem, visited)
+			if err == nil {
+				return
+			}
+			if err == SkipAll {
+				mu.Lock()
+				skipAll = true
+				mu.Unlock()
+			} else {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+			cancel()
+		}()
+	}
+
+	var rootDone sync.WaitGroup
+	dispatch(walkParallelTask{dirfd: dirfd, name: name, relative: ".", entry: iofs.FileInfoToDirEntry(info)}, &rootDone)
+	rootDone.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if skipAll || len(errs) == 0 {
+		return nil
+	}
+	return errors.Join(errs...)
+}
+
+func (fs *UnixFS) walkDirParallel(ctx context.Context, parentfd int, name, relative string, d DirEntry, opts WalkOptions, walkDirFn WalkDiratFunc, dispatch func(walkParallelTask, *sync.WaitGroup), sem chan struct{}, visited *symlinkGuard) error {
+	if err := walkDirFn(parentfd, name, relative, d, nil); err != nil || !d.IsDir() {
+		if err == SkipDir && d.IsDir() {
+			return nil
+		}
+		return err
+	}
+
+	// Hold a semaphore slot only for the open+readdir syscalls below, not
+	// for the time spent waiting on dispatched children: those children
+	// need slots of their own, and a tree deeper than len(sem) would
+	// otherwise deadlock with every slot held by an ancestor blocked on its
+	// descendants.
+	sem <- struct{}{}
+	dirfd, err := fs.openatAny(parentfd, name, O_DIRECTORY|O_RDONLY, 0)
+	if err != nil {
+		<-sem
+		return err
+	}
+
+	b := scratchPool.Get().([]byte)
+	dirs, err := fs.readDir(dirfd, name, relative, b)
+	scratchPool.Put(b)
+	<-sem
+
+	if err != nil {
+		if err := walkDirFn(dirfd, name, relative, d, err); err != nil {
+			unix.Close(dirfd)
+			if err == SkipDir && d.IsDir() {
+				return nil
+			}
+			return err
+		}
+	}
+
+	if opts.SortEntries {
+		sort.Slice(dirs, func(i, j int) bool { return dirs[i].Name() < dirs[j].Name() })
+	}
+
+	// children is waited on before closing dirfd, since dispatched children
+	// read from it asynchronously and may outlive this call frame.
+	var children sync.WaitGroup
+	defer func() {
+		children.Wait()
+		unix.Close(dirfd)
+	}()
+
+	for _, d1 := range dirs {
+		if ctx.Err() != nil {
+			return nil
+		}
+		childName := d1.Name()
+		var rel string
+		if relative == "." {
+			rel = childName
+		} else {
+			rel = relative + "/" + childName
+		}
+
+		entry := d1
+		isSymlink := d1.Type()&ModeSymlink != 0
+		if isSymlink && opts.FollowSymlinks {
+			target, err := fs.Statat(dirfd, childName)
+			if err != nil {
+				if err := walkDirFn(dirfd, childName, rel, d1, err); err != nil {
+					if err == SkipDir {
+						continue
+					}
+					return err
+				}
+				continue
+			}
+			if target.IsDir() && visited.visit(target) {
+				entry = followedSymlinkEntry{d1}
+			}
+		}
+
+		if entry.IsDir() {
+			dispatch(walkParallelTask{dirfd: dirfd, name: childName, relative: rel, entry: entry}, &children)
+			continue
+		}
+		if err := walkDirFn(dirfd, childName, rel, d1, nil); err != nil {
+			if err == SkipDir {
+				break
+			}
+			return err
+		}
+	}
+
+	return nil
+}
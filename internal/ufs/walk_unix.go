@@ -12,7 +12,7 @@ import (
 	iofs "io/fs"
 	"os"
 	"path"
-	"reflect"
+	"sync"
 	"unsafe"
 
 	"golang.org/x/sys/unix"
@@ -21,12 +21,13 @@ import (
 type WalkDiratFunc func(dirfd int, name, relative string, d DirEntry, err error) error
 
 func (fs *UnixFS) WalkDirat(dirfd int, name string, fn WalkDiratFunc) error {
-	info, err := fs.Lstatat(dirfd, name)
+	info, err := fs.lstatatAny(dirfd, name)
 	if err != nil {
 		err = fn(dirfd, name, ".", nil, err)
 	} else {
-		b := newScratchBuffer()
+		b := scratchPool.Get().([]byte)
 		err = fs.walkDir(b, dirfd, name, ".", iofs.FileInfoToDirEntry(info), fn)
+		scratchPool.Put(b)
 	}
 	if err == SkipDir || err == SkipAll {
 		return nil
@@ -43,7 +44,7 @@ func (fs *UnixFS) walkDir(b []byte, parentfd int, name, relative string, d DirEn
 		return err
 	}
 
-	dirfd, err := fs.openat(parentfd, name, O_DIRECTORY|O_RDONLY, 0)
+	dirfd, err := fs.openatAny(parentfd, name, O_DIRECTORY|O_RDONLY, 0)
 	if dirfd != 0 {
 		defer unix.Close(dirfd)
 	}
@@ -125,38 +126,26 @@ func ReadDirMap[T any](fs *UnixFS, path string, fn func(DirEntry) (T, error)) ([
 // nameOffset is a compile time constant
 const nameOffset = int(unsafe.Offsetof(unix.Dirent{}.Name))
 
-func nameFromDirent(de *unix.Dirent) (name []byte) {
+func nameFromDirent(de *unix.Dirent) []byte {
 	// Because this GOOS' syscall.Dirent does not provide a field that specifies
 	// the name length, this function must first calculate the max possible name
 	// length, and then search for the NULL byte.
 	ml := int(de.Reclen) - nameOffset
 
-	// Convert syscall.Dirent.Name, which is array of int8, to []byte, by
-	// overwriting Cap, Len, and Data slice header fields to the max possible
-	// name length computed above, and finding the terminating NULL byte.
-	//
-	// TODO: is there an alternative to the deprecated SliceHeader?
-	// SliceHeader was mainly deprecated due to it being misused for avoiding
-	// allocations when converting a byte slice to a string, ref;
-	// https://go.dev/issue/53003
-	sh := (*reflect.SliceHeader)(unsafe.Pointer(&name))
-	sh.Cap = ml
-	sh.Len = ml
-	sh.Data = uintptr(unsafe.Pointer(&de.Name[0]))
+	// unsafe.Slice builds a []byte view directly over de.Name's backing array
+	// without the deprecated reflect.SliceHeader trick; de outlives the
+	// returned slice for the duration of the caller's use, same as before.
+	name := unsafe.Slice((*byte)(unsafe.Pointer(&de.Name[0])), ml)
 
 	if index := bytes.IndexByte(name, 0); index >= 0 {
-		// Found NULL byte; set slice's cap and len accordingly.
-		sh.Cap = index
-		sh.Len = index
-		return
+		// Found NULL byte; trim to it.
+		return name[:index]
 	}
 
 	// NOTE: This branch is not expected, but included for defensive
 	// programming, and provides a hard stop on the name based on the structure
 	// field array size.
-	sh.Cap = len(de.Name)
-	sh.Len = sh.Cap
-	return
+	return name[:len(de.Name)]
 }
 
 // modeTypeFromDirent converts a syscall defined constant, which is in purview
@@ -197,7 +186,7 @@ func (fs *UnixFS) modeTypeFromDirent(de *unix.Dirent, fd int, name string) (File
 // that are provided by stat but not by the syscall, so users can rely on their
 // values.
 func (fs *UnixFS) modeType(dirfd int, name string) (FileMode, error) {
-	fi, err := fs.Lstatat(dirfd, name)
+	fi, err := fs.lstatatAny(dirfd, name)
 	if err != nil {
 		return 0, fmt.Errorf("ufs: error finding mode type for %s during readDir: %w", name, err)
 	}
@@ -293,7 +282,7 @@ func (de dirent) Info() (FileInfo, error) {
 	if de.fs == nil {
 		return nil, nil
 	}
-	return de.fs.Lstatat(de.dirfd, de.name)
+	return de.fs.lstatatAny(de.dirfd, de.name)
 	// return de.fs.Lstat(de.path)
 }
 
@@ -301,7 +290,7 @@ func (de dirent) Open() (File, error) {
 	if de.fs == nil {
 		return nil, nil
 	}
-	return de.fs.OpenFileat(de.dirfd, de.name, O_RDONLY, 0)
+	return de.fs.openFileatAny(de.dirfd, de.name, O_RDONLY, 0)
 	// return de.fs.OpenFile(de.path, O_RDONLY, 0)
 }
 
@@ -1,7 +1,6 @@
 package server
 
 import (
-	"fmt"
 	"sync"
 	"time"
 
@@ -16,79 +15,212 @@ var appName string
 var appNameSync sync.Once
 
 // PublishConsoleOutputFromDaemon sends output to the server console formatted
-// to appear correctly as being sent from Wings.
+// to appear correctly as being sent from Wings. The rendering itself is
+// delegated to the configured ConsoleFormatter so integrators can rebrand or
+// restructure the banner without forking Wings; see RegisterConsoleFormatter.
 func (s *Server) PublishConsoleOutputFromDaemon(data string) {
-    // ANSI escape-коды для цветов
-    const (
-        Reset      = "\033[0m"
-        White      = "\033[97m"
-        Purple     = "\033[95m"       // Основной фиолетовый
-        LightPink  = "\033[38;5;219m" // Розовый (для "System")
-        LightPurple = "\033[38;5;225m" // Светло-фиолетовый (для data)
-    )
-
-    // Форматирование строки с использованием ANSI-кодов
-    formattedOutput := fmt.Sprintf(
-        "%s[%sCrystallSpace %sSystem%s] %s%s%s%s",
-        White, Purple, LightPink, White, LightPurple, data, Reset, White,
-    )
-
-    // Публикация события с отформатированным текстом
-    s.Events().Publish(
-        ConsoleOutputEvent,
-        formattedOutput,
-    )
+	s.publishConsoleOutputFromDaemon("info", data)
 }
 
+func (s *Server) publishConsoleOutputFromDaemon(level, data string) {
+	// Freeform daemon text has no stable id of its own, so it's keyed by
+	// its own content; built-in messages that need to survive rewording
+	// should go through publishTranslatedConsoleOutputFromDaemon instead.
+	s.publishTranslatedConsoleOutputFromDaemon(level, data, data)
+}
+
+// publishTranslatedConsoleOutputFromDaemon is like
+// publishConsoleOutputFromDaemon, but looks fallback's translation up by the
+// stable id rather than by fallback's own text, so a built-in message can be
+// reworded without silently breaking every integrator's translation
+// catalog.
+func (s *Server) publishTranslatedConsoleOutputFromDaemon(level, id, fallback string) {
+	cfg := config.Get().System.Console
+	data := translateDaemonMessage(cfg, id, fallback)
+	formatter := consoleFormatter(cfg)
+
+	consoleTailFor(s).append(data)
+
+	s.Events().Publish(ConsoleOutputEvent, ConsoleOutputPayload{
+		Source:   "daemon",
+		Level:    level,
+		Raw:      data,
+		Rendered: formatter.FormatDaemonMessage(level, data),
+	})
+}
+
+// Stable ids for built-in daemon messages that may be translated through
+// ConsoleConfiguration.Messages. These are looked up directly, independent
+// of the fallback display text, so a future wording tweak to the fallback
+// doesn't silently break every configured translation catalog.
+const (
+	msgConsoleThrottleWarn      = "console.throttle.warn"
+	msgConsoleThrottleTerminate = "console.throttle.terminate"
+)
+
+// ConsoleThrottleEventTopic is the event topic that ConsoleThrottleEvent
+// updates are published under, separate from ConsoleOutputEvent so panels
+// can subscribe to throttle state without filtering console output lines.
+const ConsoleThrottleEventTopic = "console throttle"
 
-// Throttler returns the throttler instance for the server or creates a new one.
+// ConsoleThrottleEvent carries the throttler's live token-bucket state so
+// panels can visualize throttling (e.g. a meter ticking down) in real time,
+// rather than only learning about it after a strike fires.
+type ConsoleThrottleEvent struct {
+	Tokens float64 `json:"tokens"`
+	Rate   float64 `json:"rate"`
+	Burst  float64 `json:"burst"`
+}
+
+// consoleThrottleEventInterval caps how often ConsoleThrottle emits a
+// steady-state ConsoleThrottleEvent while Allow() is called once per console
+// line; warn/terminate/recover transitions always emit immediately
+// regardless of this interval.
+const consoleThrottleEventInterval = 250 * time.Millisecond
+
+// Throttler returns the throttler instance for the server or creates a new
+// one, applying the server's own console_throttles override if one is
+// configured, and otherwise falling back to the global default.
 func (s *Server) Throttler() *ConsoleThrottle {
 	s.throttleOnce.Do(func() {
 		throttles := config.Get().Throttles
-		period := time.Duration(throttles.Period) * time.Millisecond
+		if override := s.Config().Throttles; override != nil {
+			throttles = *override
+		}
 
-		s.throttler = newConsoleThrottle(throttles.Lines, period)
-		s.throttler.strike = func() {
-			s.PublishConsoleOutputFromDaemon("Сервер выводит данные на консоль слишком быстро — ограничение скорости...")
+		s.throttler = newConsoleThrottle(throttles)
+		s.throttler.warn = func() {
+			s.publishTranslatedConsoleOutputFromDaemon("info", msgConsoleThrottleWarn, "Сервер выводит данные на консоль слишком быстро — приближение к ограничению скорости...")
+		}
+		s.throttler.terminate = func() {
+			s.publishTranslatedConsoleOutputFromDaemon("info", msgConsoleThrottleTerminate, "Сервер выводит данные на консоль слишком быстро — ограничение скорости...")
+			s.Environment().Terminate()
+		}
+		s.throttler.event = func(e ConsoleThrottleEvent) {
+			s.Events().Publish(ConsoleThrottleEventTopic, e)
 		}
 	})
 	return s.throttler
 }
 
+// ConsoleThrottle is a graduated, token-bucket backed rate limiter for
+// console output. A first breach only warns; a breach sustained past
+// TerminateAfter escalates to terminating the server environment, the same
+// as a single-strike limiter would, but recovering within RecoverAfter of
+// staying under the limit clears the strike automatically instead of
+// requiring a caller to invoke Reset.
 type ConsoleThrottle struct {
-	limit  *system.Rate
-	lock   *system.Locker
-	strike func()
+	bucket  *system.TokenBucket
+	enabled bool
+
+	warnAfter      time.Duration
+	terminateAfter time.Duration
+	recoverAfter   time.Duration
+
+	mu           sync.Mutex
+	breachSince  time.Time
+	recoverSince time.Time
+	warned       bool
+	terminated   bool
+	lastEventAt  time.Time
+
+	warn      func()
+	terminate func()
+	event     func(ConsoleThrottleEvent)
 }
 
-func newConsoleThrottle(lines uint64, period time.Duration) *ConsoleThrottle {
+func newConsoleThrottle(cfg config.ConsoleThrottles) *ConsoleThrottle {
+	period := time.Duration(cfg.Period) * time.Millisecond
 	return &ConsoleThrottle{
-		limit: system.NewRate(lines, period),
-		lock:  system.NewLocker(),
+		bucket:         system.NewTokenBucket(float64(cfg.Lines), float64(cfg.Burst), period),
+		enabled:        cfg.Enabled,
+		warnAfter:      time.Duration(cfg.WarnAfter) * time.Millisecond,
+		terminateAfter: time.Duration(cfg.TerminateAfter) * time.Millisecond,
+		recoverAfter:   time.Duration(cfg.RecoverAfter) * time.Millisecond,
 	}
 }
 
-// Allow checks if the console is allowed to process more output data, or if too
-// much has already been sent over the line. If there is too much output the
-// strike callback function is triggered, but only if it has not already been
-// triggered at this point in the process.
-//
-// If output is allowed, the lock on the throttler is released and the next time
-// it is triggered the strike function will be re-executed.
+// Allow checks if the console is allowed to process more output data, or if
+// too much has already been sent over the line. A breach is only warned
+// about once it has persisted past warnAfter, and escalates to terminate
+// once sustained past terminateAfter. Output back under the limit for
+// recoverAfter automatically clears the strike, so callers never need to
+// call Reset themselves. Allow always returns true when the throttle is
+// disabled via config.
 func (ct *ConsoleThrottle) Allow() bool {
-	if !ct.limit.Try() {
-		if err := ct.lock.Acquire(); err == nil {
-			if ct.strike != nil {
-				ct.strike()
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	if !ct.enabled {
+		return true
+	}
+
+	now := time.Now()
+	stateChanged := false
+
+	if ct.bucket.Allow() {
+		ct.breachSince = time.Time{}
+		if ct.warned || ct.terminated {
+			if ct.recoverSince.IsZero() {
+				ct.recoverSince = now
+			} else if now.Sub(ct.recoverSince) >= ct.recoverAfter {
+				ct.warned = false
+				ct.terminated = false
+				ct.recoverSince = time.Time{}
+				stateChanged = true
 			}
 		}
-		return false
+		ct.emitEvent(now, stateChanged)
+		return true
+	}
+
+	ct.recoverSince = time.Time{}
+	if ct.breachSince.IsZero() {
+		ct.breachSince = now
+	}
+
+	if !ct.warned && now.Sub(ct.breachSince) >= ct.warnAfter {
+		ct.warned = true
+		stateChanged = true
+		if ct.warn != nil {
+			ct.warn()
+		}
+	}
+	if !ct.terminated && now.Sub(ct.breachSince) >= ct.terminateAfter {
+		ct.terminated = true
+		stateChanged = true
+		if ct.terminate != nil {
+			ct.terminate()
+		}
+	}
+	ct.emitEvent(now, stateChanged)
+	return false
+}
+
+// emitEvent publishes a ConsoleThrottleEvent immediately on a warn/terminate/
+// recover transition, and otherwise at most once per
+// consoleThrottleEventInterval, so steady per-line output during a breach
+// doesn't double the event-bus traffic the throttle exists to cut down.
+func (ct *ConsoleThrottle) emitEvent(now time.Time, stateChanged bool) {
+	if ct.event == nil {
+		return
+	}
+	if !stateChanged && now.Sub(ct.lastEventAt) < consoleThrottleEventInterval {
+		return
 	}
-	ct.lock.Release()
-	return true
+	ct.lastEventAt = now
+	ct.event(ConsoleThrottleEvent{Tokens: ct.bucket.Tokens(), Rate: ct.bucket.Rate, Burst: ct.bucket.Burst})
 }
 
-// Reset resets the console throttler internal rate limiter and overage counter.
+// Reset clears any active strike and refills the underlying token bucket,
+// for callers that want to forgive a breach immediately rather than waiting
+// for the automatic recovery window.
 func (ct *ConsoleThrottle) Reset() {
-	ct.limit.Reset()
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.bucket.Reset()
+	ct.breachSince = time.Time{}
+	ct.recoverSince = time.Time{}
+	ct.warned = false
+	ct.terminated = false
 }
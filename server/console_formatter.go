@@ -0,0 +1,156 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/pterodactyl/wings/config"
+)
+
+// ConsoleOutputPayload is the payload published under the ConsoleOutputEvent
+// topic for daemon-originated console messages, carrying both the raw
+// message and its rendered form so that panels can choose to render the
+// event themselves instead of parsing the rendered string.
+type ConsoleOutputPayload struct {
+	// Source is always "daemon" for messages published through
+	// PublishConsoleOutputFromDaemon; it exists to distinguish these events
+	// from ordinary "process" console output on the same event name.
+	Source string `json:"source"`
+	// Level is a free-form severity tag such as "info" or "warning".
+	Level string `json:"level"`
+	// Raw is the message text after locale translation (see
+	// ConsoleConfiguration.Locale) but before formatting.
+	Raw string `json:"raw"`
+	// Rendered is the output of the configured ConsoleFormatter.
+	Rendered string `json:"rendered"`
+}
+
+// ConsoleFormatter renders a daemon-originated console message for display.
+// Implementations are free to ignore level, or to render nothing at all and
+// let the receiving panel build its own representation from the
+// accompanying ConsoleOutputEvent.
+type ConsoleFormatter interface {
+	FormatDaemonMessage(level, msg string) string
+}
+
+var (
+	consoleFormattersMu sync.RWMutex
+	// consoleFormatters holds formatters registered by integrators under a
+	// custom name; the built-in "plain"/"ansi"/"json" names are handled
+	// directly by consoleFormatter so they can be parameterized from
+	// ConsoleConfiguration on every call instead of being fixed at registry
+	// time.
+	consoleFormatters = map[string]ConsoleFormatter{}
+)
+
+// RegisterConsoleFormatter makes a ConsoleFormatter available under name for
+// selection via config.ConsoleConfiguration.Formatter, so integrators can
+// rebrand or restructure the console banner without forking Wings. name must
+// not be "plain", "ansi", or "json", which are reserved for the built-ins.
+func RegisterConsoleFormatter(name string, formatter ConsoleFormatter) {
+	consoleFormattersMu.Lock()
+	defer consoleFormattersMu.Unlock()
+	consoleFormatters[name] = formatter
+}
+
+// consoleFormatter builds the ConsoleFormatter selected by cfg.Formatter,
+// applying cfg's Prefix, Palette, and EnableANSI to the built-in formatters
+// on every call so config changes take effect without a restart. Unknown
+// names fall back to a formatter registered via RegisterConsoleFormatter,
+// and finally to PlainFormatter.
+func consoleFormatter(cfg config.ConsoleConfiguration) ConsoleFormatter {
+	switch cfg.Formatter {
+	case "plain":
+		return PlainFormatter{}
+	case "json":
+		return JSONFormatter{}
+	case "", "ansi":
+		if !cfg.EnableANSI {
+			return PlainFormatter{}
+		}
+		prefix := cfg.Prefix
+		if prefix == "" {
+			prefix = "Daemon"
+		}
+		return ANSIFormatter{Prefix: prefix, Palette: cfg.Palette}
+	}
+
+	consoleFormattersMu.RLock()
+	defer consoleFormattersMu.RUnlock()
+	if f, ok := consoleFormatters[cfg.Formatter]; ok {
+		return f
+	}
+	return PlainFormatter{}
+}
+
+// translateDaemonMessage looks id up in cfg's locale catalog, returning the
+// translated string when cfg.Locale has an entry for it and fallback
+// otherwise (including when no locale is configured). id should be a stable
+// key (see the msg* constants in console.go) rather than display text, so
+// that rewording fallback doesn't silently break an integrator's catalog.
+func translateDaemonMessage(cfg config.ConsoleConfiguration, id, fallback string) string {
+	if cfg.Locale == "" {
+		return fallback
+	}
+	if catalog, ok := cfg.Messages[cfg.Locale]; ok {
+		if translated, ok := catalog[id]; ok {
+			return translated
+		}
+	}
+	return fallback
+}
+
+// PlainFormatter renders daemon messages with no prefix and no color,
+// suitable for panels that render their own chrome around console output.
+type PlainFormatter struct{}
+
+func (PlainFormatter) FormatDaemonMessage(level, msg string) string {
+	return msg
+}
+
+// ANSIFormatter renders daemon messages with an ANSI-colored prefix, the
+// successor to the hardcoded banner PublishConsoleOutputFromDaemon used to
+// emit directly.
+type ANSIFormatter struct {
+	Prefix string
+	// Palette maps "prefix", "body", and "reset" to ANSI escape sequences.
+	// Zero values fall back to a neutral white/yellow scheme.
+	Palette map[string]string
+}
+
+func (f ANSIFormatter) color(key, fallback string) string {
+	if c, ok := f.Palette[key]; ok {
+		return c
+	}
+	return fallback
+}
+
+func (f ANSIFormatter) FormatDaemonMessage(level, msg string) string {
+	reset := f.color("reset", "\033[0m")
+	prefixColor := f.color("prefix", "\033[33;1m")
+	bodyColor := f.color("body", "\033[37m")
+	prefix := f.Prefix
+	if prefix == "" {
+		prefix = "Daemon"
+	}
+	return fmt.Sprintf("%s[%s%s%s]: %s%s%s", reset, prefixColor, prefix, reset, bodyColor, msg, reset)
+}
+
+// JSONFormatter renders daemon messages as a JSON object, for integrators
+// who parse console output programmatically rather than displaying it
+// directly.
+type JSONFormatter struct{}
+
+func (JSONFormatter) FormatDaemonMessage(level, msg string) string {
+	out, err := json.Marshal(struct {
+		Level   string `json:"level"`
+		Message string `json:"message"`
+	}{Level: level, Message: msg})
+	if err != nil {
+		// json.Marshal only fails on unsupported types, which cannot occur
+		// for the fixed struct above; fall back to the raw message.
+		return msg
+	}
+	return string(out)
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/pterodactyl/wings/config"
+	"github.com/pterodactyl/wings/internal/ufs/fuse"
+)
+
+// quotaCheckerFunc adapts a plain func to fuse.QuotaChecker, the same way
+// http.HandlerFunc adapts a func to http.Handler.
+type quotaCheckerFunc func(n int64) bool
+
+func (f quotaCheckerFunc) Allow(n int64) bool { return f(n) }
+
+// fusePathPolicy rejects the fixed set of paths config.FuseConfiguration
+// lists as disallowed, so a process inside the container can't create,
+// write to, or remove the management files Wings itself relies on through
+// the mount.
+type fusePathPolicy struct {
+	disallowed map[string]struct{}
+}
+
+func newFusePathPolicy(names []string) fusePathPolicy {
+	disallowed := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		disallowed[name] = struct{}{}
+	}
+	return fusePathPolicy{disallowed: disallowed}
+}
+
+func (p fusePathPolicy) Allow(relPath string) bool {
+	_, blocked := p.disallowed[relPath]
+	return !blocked
+}
+
+// FuseMount returns the server's FUSE mount, preparing (but not mounting) it
+// on first call. It returns nil when FUSE is disabled in config or
+// unavailable on this host, in which case callers should continue using the
+// server's plain UnixFS sandbox. On first call it also registers the
+// server's virtual stats/console files and hooks EnableFuseMount/
+// DisableFuseMount into the environment's power-state lifecycle.
+func (s *Server) FuseMount() *fuse.Mount {
+	s.fuseMountOnce.Do(func() {
+		cfg := config.Get().System.Fuse
+		if !cfg.Enabled || !fuse.Available() {
+			return
+		}
+
+		limit := quotaCheckerFunc(func(n int64) bool {
+			return s.Filesystem().HasSpaceAvailable(n)
+		})
+		policy := newFusePathPolicy(cfg.DisallowedFiles)
+
+		s.fuseMount = fuse.NewMount(filepath.Join(cfg.MountRoot, s.ID()), s.Filesystem().UnixFS(), limit, policy, fuse.MountOptions{
+			AllowOther:  cfg.AllowOther,
+			MaxWrite:    cfg.MaxWrite,
+			DirectIO:    cfg.DirectIO,
+			ReaddirPlus: cfg.ReaddirPlus,
+			Uid:         cfg.Uid,
+			Gid:         cfg.Gid,
+		})
+
+		root := s.fuseMount.Root()
+		root.RegisterVirtualFile(context.Background(), ".wings/stats", fuseStatsFile{s})
+		root.RegisterVirtualFile(context.Background(), ".wings/console", fuseConsoleFile{s})
+
+		s.Environment().OnBeforeStart(s.EnableFuseMount)
+		s.Environment().OnAfterStop(s.DisableFuseMount)
+	})
+	return s.fuseMount
+}
+
+// EnableFuseMount mounts the server's FUSE-backed root if FUSE is enabled
+// and available, and is a no-op otherwise so the environment can call it
+// unconditionally from its OnBeforeStart hook without checking config first.
+func (s *Server) EnableFuseMount() error {
+	m := s.FuseMount()
+	if m == nil {
+		return nil
+	}
+	if err := m.Mount(); err != nil {
+		return fmt.Errorf("server: failed to mount fuse root for %s: %w", s.ID(), err)
+	}
+	return nil
+}
+
+// DisableFuseMount unmounts the server's FUSE-backed root, if one was
+// mounted, so it doesn't outlive the container using it. Called from the
+// environment's OnAfterStop hook, mirroring EnableFuseMount's OnBeforeStart.
+func (s *Server) DisableFuseMount() error {
+	m := s.FuseMount()
+	if m == nil {
+		return nil
+	}
+	if err := m.Unmount(); err != nil {
+		return fmt.Errorf("server: failed to unmount fuse root for %s: %w", s.ID(), err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+// consoleTailLines is the number of recent daemon console lines
+// fuseConsoleFile keeps around for a process inside the container to read.
+const consoleTailLines = 200
+
+var (
+	consoleTailsMu sync.Mutex
+	// consoleTails holds one ring buffer per server, keyed by server ID,
+	// rather than a field on Server, so the FUSE virtual-file wiring
+	// doesn't need to touch Server's own definition.
+	consoleTails = map[string]*consoleTailBuffer{}
+)
+
+// consoleTailBuffer is a small ring buffer of recent console lines, backing
+// the ".wings/console" virtual file.
+type consoleTailBuffer struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func consoleTailFor(s *Server) *consoleTailBuffer {
+	consoleTailsMu.Lock()
+	defer consoleTailsMu.Unlock()
+	b, ok := consoleTails[s.ID()]
+	if !ok {
+		b = &consoleTailBuffer{}
+		consoleTails[s.ID()] = b
+	}
+	return b
+}
+
+func (b *consoleTailBuffer) append(line string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lines = append(b.lines, line)
+	if len(b.lines) > consoleTailLines {
+		b.lines = b.lines[len(b.lines)-consoleTailLines:]
+	}
+}
+
+func (b *consoleTailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return strings.Join(b.lines, "\n")
+}
+
+// fuseConsoleFile exposes the server's recent daemon-originated console
+// messages as plain text at ".wings/console", so a process inside the
+// container can see why Wings throttled or terminated it without a
+// websocket connection back to the panel. It covers only daemon-originated
+// messages (those published through PublishConsoleOutputFromDaemon and the
+// built-in throttle messages), not the process's own stdout/stderr.
+type fuseConsoleFile struct {
+	server *Server
+}
+
+func (f fuseConsoleFile) ReadAll(ctx context.Context) ([]byte, error) {
+	return []byte(consoleTailFor(f.server).String()), nil
+}
+
+// fuseStatsFile exposes the server's live console-throttle state as JSON at
+// ".wings/stats", so a process inside the container can tell how close it
+// is to being throttled or terminated without a websocket connection back
+// to the panel.
+type fuseStatsFile struct {
+	server *Server
+}
+
+func (f fuseStatsFile) ReadAll(ctx context.Context) ([]byte, error) {
+	ct := f.server.Throttler()
+	ct.mu.Lock()
+	event := ConsoleThrottleEvent{
+		Tokens: ct.bucket.Tokens(),
+		Rate:   ct.bucket.Rate,
+		Burst:  ct.bucket.Burst,
+	}
+	ct.mu.Unlock()
+	return json.Marshal(event)
+}
@@ -0,0 +1,72 @@
+package system
+
+import (
+	"sync"
+	"time"
+)
+
+// TokenBucket is a burstable rate limiter: up to Burst tokens accumulate at
+// Rate tokens per Refill interval, and each Allow call consumes one token.
+// Unlike a fixed window limiter, a caller that has been quiet can spend a
+// short burst of tokens (e.g. a server's startup log spam) without being
+// penalized the way a steady rate/period window would.
+type TokenBucket struct {
+	Rate   float64
+	Burst  float64
+	Refill time.Duration
+
+	mu       sync.Mutex
+	tokens   float64
+	lastFill time.Time
+}
+
+// NewTokenBucket creates a token bucket starting at full capacity.
+func NewTokenBucket(rate, burst float64, refill time.Duration) *TokenBucket {
+	return &TokenBucket{
+		Rate:     rate,
+		Burst:    burst,
+		Refill:   refill,
+		tokens:   burst,
+		lastFill: time.Now(),
+	}
+}
+
+// Allow reports whether a token is available and, if so, consumes it.
+func (tb *TokenBucket) Allow() bool {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.refill()
+	if tb.tokens < 1 {
+		return false
+	}
+	tb.tokens--
+	return true
+}
+
+// refill must be called with tb.mu held.
+func (tb *TokenBucket) refill() {
+	now := time.Now()
+	if elapsed := now.Sub(tb.lastFill); elapsed > 0 && tb.Refill > 0 {
+		tb.tokens += elapsed.Seconds() / tb.Refill.Seconds() * tb.Rate
+		if tb.tokens > tb.Burst {
+			tb.tokens = tb.Burst
+		}
+	}
+	tb.lastFill = now
+}
+
+// Tokens returns the current, post-refill token count for observability.
+func (tb *TokenBucket) Tokens() float64 {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.refill()
+	return tb.tokens
+}
+
+// Reset refills the bucket back to full capacity.
+func (tb *TokenBucket) Reset() {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.tokens = tb.Burst
+	tb.lastFill = time.Now()
+}